@@ -0,0 +1,22 @@
+package engine
+
+// DefaultPort is the TCP port the docker daemon listens on when a driver
+// doesn't request a specific one.
+const DefaultPort = 2376
+
+// Options carries the engine configuration a provisioner renders into the
+// daemon's systemd unit(s), as read from the CLI flags.
+type Options struct {
+	ArbitraryFlags   []string
+	Env              []string
+	InsecureRegistry []string
+	Labels           []string
+	RegistryMirror   []string
+	StorageDriver    string
+	TLSVerify        bool
+
+	// Runtime selects the container runtime the provisioner installs and
+	// configures: "docker" (the default, used when empty), "containerd",
+	// or "cri-o". See provision/runtime for the supported set.
+	Runtime string
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/docker/machine/libmachine/engine"
 	"github.com/docker/machine/libmachine/log"
 	"github.com/docker/machine/libmachine/provision/pkgaction"
+	"github.com/docker/machine/libmachine/provision/runtime"
 	"github.com/docker/machine/libmachine/swarm"
 	"github.com/docker/machine/libmachine/versioncmp"
 	"github.com/docker/machine/libmachine/provision/serviceaction"
@@ -54,6 +55,35 @@ func (provisioner *ClearLinuxProvisioner) GenerateDockerOptions(dockerPort int)
 	driverNameLabel := fmt.Sprintf("provider=%s", provisioner.Driver.DriverName())
 	provisioner.EngineOptions.Labels = append(provisioner.EngineOptions.Labels, driverNameLabel)
 
+	if runtimeName := provisioner.EngineOptions.Runtime; runtimeName != "" && runtimeName != "docker" {
+		rt, ok := runtime.Get(runtimeName)
+		if !ok {
+			return nil, fmt.Errorf("unsupported runtime: %q", runtimeName)
+		}
+
+		// The provider label appended above is a dockerd --label of the
+		// provisioner's own, not a user setting, so it is left out here;
+		// otherwise every non-docker runtime would reject Labels.
+		userLabels := provisioner.EngineOptions.Labels[:len(provisioner.EngineOptions.Labels)-1]
+
+		unit, err := rt.GenerateUnit(provisioner, runtime.Config{
+			DockerPort:       dockerPort,
+			Labels:           userLabels,
+			Env:              provisioner.EngineOptions.Env,
+			InsecureRegistry: provisioner.EngineOptions.InsecureRegistry,
+			RegistryMirror:   provisioner.EngineOptions.RegistryMirror,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &DockerOptions{
+			UnitFiles: []UnitFile{
+				{Path: unit.Path, Contents: unit.Contents},
+			},
+		}, nil
+	}
+
 	dockerVersion, err := DockerClientVersion(provisioner)
 	if err != nil {
 		return nil, err
@@ -64,10 +94,13 @@ func (provisioner *ClearLinuxProvisioner) GenerateDockerOptions(dockerPort int)
 		arg = ""
 	}
 
+	// docker.service no longer binds the TCP port itself: docker.socket
+	// owns the listening FD and hands it to dockerd on activation, so a
+	// daemon restart (or cert rotation) never drops the listener.
 	engineConfigTmpl := `[Service]
 Environment=TMPDIR=/var/tmp
 ExecStart=
-ExecStart=/usr/bin/dockerd ` + arg + ` --host=unix:///var/run/docker.sock --host=tcp://0.0.0.0:{{.DockerPort}} --tlsverify --tlscacert {{.AuthOptions.CaCertRemotePath}} --tlscert {{.AuthOptions.ServerCertRemotePath}} --tlskey {{.AuthOptions.ServerKeyRemotePath}}{{ range .EngineOptions.Labels }} --label {{.}}{{ end }}{{ range .EngineOptions.InsecureRegistry }} --insecure-registry {{.}}{{ end }}{{ range .EngineOptions.RegistryMirror }} --registry-mirror {{.}}{{ end }}{{ range .EngineOptions.ArbitraryFlags }} --{{.}}{{ end }} \$DOCKER_OPTS \$DOCKER_OPT_BIP \$DOCKER_OPT_MTU \$DOCKER_OPT_IPMASQ
+ExecStart=/usr/bin/dockerd ` + arg + ` --host fd:// --tlsverify --tlscacert {{.AuthOptions.CaCertRemotePath}} --tlscert {{.AuthOptions.ServerCertRemotePath}} --tlskey {{.AuthOptions.ServerKeyRemotePath}}{{ range .EngineOptions.Labels }} --label {{.}}{{ end }}{{ range .EngineOptions.InsecureRegistry }} --insecure-registry {{.}}{{ end }}{{ range .EngineOptions.RegistryMirror }} --registry-mirror {{.}}{{ end }}{{ range .EngineOptions.ArbitraryFlags }} --{{.}}{{ end }} \$DOCKER_OPTS \$DOCKER_OPT_BIP \$DOCKER_OPT_MTU \$DOCKER_OPT_IPMASQ
 Environment={{range .EngineOptions.Env}}{{ printf "%q" . }} {{end}}
 `
 
@@ -86,33 +119,40 @@ Environment={{range .EngineOptions.Env}}{{ printf "%q" . }} {{end}}
 
 	t.Execute(&engineCfg, engineConfigContext)
 
+	socketCfg := fmt.Sprintf(`[Socket]
+ListenStream=
+ListenStream=/var/run/docker.sock
+ListenStream=0.0.0.0:%d
+`, dockerPort)
+
 	return &DockerOptions{
-		EngineOptions:     engineCfg.String(),
-		EngineOptionsPath: provisioner.DaemonOptionsFile,
+		UnitFiles: []UnitFile{
+			{Path: "/etc/systemd/system/docker.socket.d/10-machine.conf", Contents: socketCfg},
+			{Path: "/etc/systemd/system/docker.service.d/10-machine.conf", Contents: engineCfg.String()},
+		},
 	}, nil
 }
 
 func (provisioner *ClearLinuxProvisioner) Package(name string, action pkgaction.PackageAction) error {
 	var packageAction string
 
-  switch action {
-  case pkgaction.Install, pkgaction.Upgrade:
-    packageAction = "bundle-add"
-  case pkgaction.Remove:
-  case pkgaction.Purge:
-    packageAction = "bundle-remove"
-  }
+	switch action {
+	case pkgaction.Install, pkgaction.Upgrade:
+		packageAction = "bundle-add"
+	case pkgaction.Remove, pkgaction.Purge:
+		packageAction = "bundle-remove"
+	}
 
-  switch name {
-  case "docker":
-    name = "containers-basic"
-  }
+	switch name {
+	case "docker":
+		name = "containers-basic"
+	}
 
-  command := fmt.Sprintf("swupd %s %s ", packageAction, name)
-  log.Debugf("package: action=%s name=%s", action.String(), name)
+	command := fmt.Sprintf("swupd %s %s ", packageAction, name)
+	log.Debugf("package: action=%s name=%s", action.String(), name)
 
-  return waitForLock(provisioner, command)
-	return nil
+	_, err := RetrySSH(provisioner, command, DefaultRetryOptions)
+	return err
 }
 
 func (provisioner *ClearLinuxProvisioner) Provision(swarmOptions swarm.Options, authOptions auth.Options, engineOptions engine.Options) error {
@@ -128,8 +168,26 @@ func (provisioner *ClearLinuxProvisioner) Provision(swarmOptions swarm.Options,
 		return err
 	}
 
-	log.Debugf("installing base package: name=containers-basic")
-	if err := provisioner.Package("containers-basic", pkgaction.Install); err != nil {
+	runtimeName := engineOptions.Runtime
+	if runtimeName == "" {
+		runtimeName = "docker"
+	}
+
+	var rt runtime.Runtime
+	if runtimeName != "docker" {
+		var ok bool
+		rt, ok = runtime.Get(runtimeName)
+		if !ok {
+			return fmt.Errorf("unsupported runtime: %q", runtimeName)
+		}
+	}
+
+	log.Debugf("installing base package for runtime: name=%s", runtimeName)
+	if runtimeName == "docker" {
+		if err := provisioner.Package("containers-basic", pkgaction.Install); err != nil {
+			return err
+		}
+	} else if err := rt.Install(provisioner); err != nil {
 		return err
 	}
 
@@ -141,14 +199,64 @@ func (provisioner *ClearLinuxProvisioner) Provision(swarmOptions swarm.Options,
 		return err
 	}
 
-	log.Debug("Configuring swarm")
-	err := configureSwarm(provisioner, swarmOptions, provisioner.AuthOptions)
+	dockerOptions, err := provisioner.GenerateDockerOptions(engine.DefaultPort)
 	if err != nil {
 		return err
 	}
 
+	log.Debug("Applying docker engine options")
+	if _, err := ApplyDockerOptionsIfChanged(provisioner, dockerOptions, serviceNameForRuntime(runtimeName, rt), provisioner.AuthOptions); err != nil {
+		return err
+	}
+
+	log.Debugf("Configuring swarm: mode=%s", swarmOptions.Mode)
+	switch swarmOptions.Mode {
+	case "swarm-mode":
+		tokens, err := configureSwarmMode(provisioner, swarmOptions)
+		if err != nil {
+			return err
+		}
+		if swarmOptions.Master {
+			// Persisted to the machine store by the libmachine/host layer,
+			// which re-reads provisioner.SwarmOptions once Provision returns.
+			provisioner.SwarmOptions.JoinTokens = tokens
+		}
+	case "none":
+		// no swarm setup requested
+	default:
+		if err := configureSwarm(provisioner, swarmOptions, provisioner.AuthOptions); err != nil {
+			return err
+		}
+	}
+
 	// enable in systemd
-	log.Debug("Enabling docker in systemd")
-	err = provisioner.Service("docker", serviceaction.Enable)
+	log.Debugf("Enabling %s in systemd", runtimeName)
+	if runtimeName == "docker" {
+		if err := provisioner.Service("docker.socket", serviceaction.Enable); err != nil {
+			return err
+		}
+		// Enable only symlinks docker.socket for next boot; restart it now
+		// so the TCP+TLS listener is actually live for this provision,
+		// not just after a reboot.
+		if err := provisioner.Service("docker.socket", serviceaction.Restart); err != nil {
+			return err
+		}
+	}
+	err = provisioner.Service(serviceNameForRuntime(runtimeName, rt), serviceaction.Enable)
 	return err
 }
+
+// serviceNameForRuntime maps a runtime to the systemd unit ClearLinux
+// installs it under; only "cri-o" diverges from its runtime name. rt is nil
+// when runtimeName is "docker", since docker isn't backed by a
+// runtime.Runtime implementation.
+func serviceNameForRuntime(runtimeName string, rt runtime.Runtime) string {
+	switch runtimeName {
+	case "docker":
+		return "docker"
+	case "cri-o":
+		return "crio"
+	default:
+		return rt.Name()
+	}
+}
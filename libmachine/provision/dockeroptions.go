@@ -0,0 +1,130 @@
+package provision
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/docker/machine/libmachine/auth"
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/provision/serviceaction"
+)
+
+// UnitFile is a systemd unit (or drop-in) a provisioner wants written to the
+// host, identified by its remote path. DockerOptions carries a slice of
+// these rather than a single rendered file, since a provisioner may need to
+// configure more than one unit (e.g. a socket-activated docker.socket
+// alongside docker.service).
+type UnitFile struct {
+	Path     string
+	Contents string
+}
+
+// hashDockerOptions hashes the rendered unit file contents together with
+// the cert bytes the daemon was configured with, so a no-op re-provision
+// can be detected and skipped while a cert rotation (which leaves the unit
+// file text itself unchanged, since it only references cert *paths*) still
+// triggers a restart. It takes the already-fetched cert contents rather
+// than reaching out over SSH itself, so it stays a pure function.
+func hashDockerOptions(opts *DockerOptions, certContents [][]byte) string {
+	h := sha256.New()
+	for _, u := range opts.UnitFiles {
+		h.Write([]byte(u.Path))
+		h.Write([]byte(u.Contents))
+	}
+	for _, c := range certContents {
+		h.Write(c)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// fetchCertContents reads the cert/key files referenced by authOptions off
+// the host, so hashDockerOptions can detect a cert rotation even though the
+// rendered unit file only ever contains their paths.
+func fetchCertContents(p Provisioner, authOptions auth.Options) ([][]byte, error) {
+	var contents [][]byte
+	for _, path := range []string{
+		authOptions.CaCertRemotePath,
+		authOptions.ServerCertRemotePath,
+		authOptions.ServerKeyRemotePath,
+	} {
+		if path == "" {
+			continue
+		}
+		out, err := p.SSHCommand(fmt.Sprintf("cat %s 2>/dev/null", path))
+		if err != nil {
+			return nil, err
+		}
+		contents = append(contents, []byte(out))
+	}
+	return contents, nil
+}
+
+// ApplyDockerOptionsIfChanged writes the rendered unit files to the
+// provisioner only if they (or the certs they reference) differ from what
+// is already on disk, and restarts serviceName only when that happens (or
+// it isn't active yet). serviceName is the systemd unit the caller
+// actually installed (e.g. "docker", "containerd", "crio") — it varies by
+// runtime, so it can't be hardcoded here. This avoids interrupting
+// long-running hosts on every re-provision.
+func ApplyDockerOptionsIfChanged(p Provisioner, opts *DockerOptions, serviceName string, authOptions auth.Options) (bool, error) {
+	certContents, err := fetchCertContents(p, authOptions)
+	if err != nil {
+		return false, err
+	}
+	newHash := hashDockerOptions(opts, certContents)
+
+	unchanged := false
+	if existing, err := p.SSHCommand(fmt.Sprintf("cat %s 2>/dev/null", hashFilePath(opts))); err == nil && strings.TrimSpace(existing) == newHash {
+		unchanged = true
+	}
+
+	if unchanged {
+		if active, err := p.SSHCommand(fmt.Sprintf("systemctl is-active %s", serviceName)); err == nil && strings.TrimSpace(active) == "active" {
+			log.Debugf("docker options unchanged and %s is active, skipping restart", serviceName)
+			return false, nil
+		}
+	}
+
+	for _, u := range opts.UnitFiles {
+		if _, err := p.SSHCommand(fmt.Sprintf("sudo mkdir -p $(dirname %s)", u.Path)); err != nil {
+			return false, err
+		}
+		if err := writeRemoteFile(p, u.Path, u.Contents); err != nil {
+			return false, err
+		}
+	}
+
+	if _, err := p.SSHCommand(fmt.Sprintf("sudo mkdir -p $(dirname %s)", hashFilePath(opts))); err != nil {
+		return false, err
+	}
+	if err := writeRemoteFile(p, hashFilePath(opts), newHash); err != nil {
+		return false, err
+	}
+
+	if err := p.Service(serviceName, serviceaction.Restart); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// hashFilePath is where we stash the last-applied options hash so repeated
+// provisions can tell whether anything actually changed.
+func hashFilePath(opts *DockerOptions) string {
+	return "/var/lib/docker-machine/options.hash"
+}
+
+// writeRemoteFile writes contents to path on the host. Contents is shipped
+// base64-encoded over SSH and decoded remotely, since Go's %q quoting
+// (escaping newlines as the literal characters "\n") survives a round trip
+// through bash double quotes as that literal text rather than a real
+// newline — multi-line unit files written that way land on disk as a
+// single unparsable line.
+func writeRemoteFile(p Provisioner, path, contents string) error {
+	encoded := base64.StdEncoding.EncodeToString([]byte(contents))
+	_, err := p.SSHCommand(fmt.Sprintf("echo %s | base64 -d | sudo tee %s", encoded, path))
+	return err
+}
@@ -0,0 +1,48 @@
+package provision
+
+import "testing"
+
+func TestHashDockerOptionsStableForSameInput(t *testing.T) {
+	opts := &DockerOptions{
+		UnitFiles: []UnitFile{
+			{Path: "/etc/systemd/system/docker.service.d/10-machine.conf", Contents: "[Service]\n"},
+		},
+	}
+	certs := [][]byte{[]byte("cert-bytes")}
+
+	first := hashDockerOptions(opts, certs)
+	second := hashDockerOptions(opts, certs)
+
+	if first != second {
+		t.Fatalf("expected stable hash for identical input, got %q and %q", first, second)
+	}
+}
+
+func TestHashDockerOptionsChangesWithUnitContents(t *testing.T) {
+	certs := [][]byte{[]byte("cert-bytes")}
+
+	base := hashDockerOptions(&DockerOptions{
+		UnitFiles: []UnitFile{{Path: "/etc/systemd/system/docker.service.d/10-machine.conf", Contents: "[Service]\n"}},
+	}, certs)
+
+	changed := hashDockerOptions(&DockerOptions{
+		UnitFiles: []UnitFile{{Path: "/etc/systemd/system/docker.service.d/10-machine.conf", Contents: "[Service]\nExecStart=/usr/bin/dockerd\n"}},
+	}, certs)
+
+	if base == changed {
+		t.Fatalf("expected hash to change when unit contents change")
+	}
+}
+
+func TestHashDockerOptionsChangesWithCertRotation(t *testing.T) {
+	opts := &DockerOptions{
+		UnitFiles: []UnitFile{{Path: "/etc/systemd/system/docker.service.d/10-machine.conf", Contents: "[Service]\n"}},
+	}
+
+	before := hashDockerOptions(opts, [][]byte{[]byte("old-cert")})
+	after := hashDockerOptions(opts, [][]byte{[]byte("new-cert")})
+
+	if before == after {
+		t.Fatalf("expected hash to change when cert contents rotate, even though unit contents (which only embed cert paths) don't")
+	}
+}
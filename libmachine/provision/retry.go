@@ -0,0 +1,102 @@
+package provision
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/docker/machine/libmachine/log"
+)
+
+// sshRunner is the subset of Provisioner RetrySSH needs. It's declared
+// locally, rather than taking a Provisioner directly, so tests can exercise
+// the backoff/deadline logic with a minimal fake instead of a full
+// Provisioner implementation.
+type sshRunner interface {
+	SSHCommand(string) (string, error)
+}
+
+// RetryOptions controls the backoff behaviour of RetrySSH.
+type RetryOptions struct {
+	// InitialInterval is the delay before the first retry.
+	InitialInterval time.Duration
+	// MaxInterval caps the delay between retries.
+	MaxInterval time.Duration
+	// MaxElapsedTime bounds the total time spent retrying before giving up.
+	MaxElapsedTime time.Duration
+}
+
+// DefaultRetryOptions is used by provisioners that don't need custom tuning.
+var DefaultRetryOptions = RetryOptions{
+	InitialInterval: 2 * time.Second,
+	MaxInterval:     30 * time.Second,
+	MaxElapsedTime:  5 * time.Minute,
+}
+
+// retryablePatterns matches stderr/stdout output that indicates a transient
+// failure worth retrying, as opposed to a genuine configuration error.
+var retryablePatterns = []*regexp.Regexp{
+	regexp.MustCompile(`Failed to connect`),
+	regexp.MustCompile(`Cannot acquire lock`),
+	regexp.MustCompile(`curl.*\b(52|56)\b`),
+	regexp.MustCompile(`HTTP/1\.[01]" 5\d\d`),
+}
+
+func isRetryableOutput(output string) bool {
+	for _, pattern := range retryablePatterns {
+		if pattern.MatchString(output) {
+			return true
+		}
+	}
+	return false
+}
+
+// RetrySSH runs cmd on p over SSH, retrying with exponential backoff while
+// the command fails with a non-zero exit and output matching one of the
+// known-transient patterns (connection drops, lock contention, CDN/mirror
+// errors). Any other failure is returned immediately.
+func RetrySSH(p sshRunner, cmd string, opts RetryOptions) (string, error) {
+	interval := opts.InitialInterval
+	if interval <= 0 {
+		interval = DefaultRetryOptions.InitialInterval
+	}
+	maxInterval := opts.MaxInterval
+	if maxInterval <= 0 {
+		maxInterval = DefaultRetryOptions.MaxInterval
+	}
+	maxElapsed := opts.MaxElapsedTime
+	if maxElapsed <= 0 {
+		maxElapsed = DefaultRetryOptions.MaxElapsedTime
+	}
+
+	deadline := time.Now().Add(maxElapsed)
+
+	var lastErr error
+	var lastOutput string
+	for {
+		output, err := p.SSHCommand(cmd)
+		if err == nil {
+			return output, nil
+		}
+
+		lastErr = err
+		lastOutput = output
+
+		if !isRetryableOutput(output) {
+			return output, err
+		}
+
+		if time.Now().Add(interval).After(deadline) {
+			break
+		}
+
+		log.Debugf("retrying command after transient failure: cmd=%q err=%v", cmd, err)
+		time.Sleep(interval)
+
+		interval *= 2
+		if interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+
+	return lastOutput, lastErr
+}
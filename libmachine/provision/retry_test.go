@@ -0,0 +1,87 @@
+package provision
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// fakeSSHRunner replays a scripted sequence of (output, error) results, one
+// per SSHCommand call, so RetrySSH's retry/backoff logic can be driven
+// without a real Provisioner.
+type fakeSSHRunner struct {
+	results []fakeSSHResult
+	calls   int
+}
+
+type fakeSSHResult struct {
+	output string
+	err    error
+}
+
+func (f *fakeSSHRunner) SSHCommand(cmd string) (string, error) {
+	i := f.calls
+	if i >= len(f.results) {
+		i = len(f.results) - 1
+	}
+	f.calls++
+	return f.results[i].output, f.results[i].err
+}
+
+func fastRetryOptions() RetryOptions {
+	return RetryOptions{
+		InitialInterval: time.Millisecond,
+		MaxInterval:     4 * time.Millisecond,
+		MaxElapsedTime:  200 * time.Millisecond,
+	}
+}
+
+func TestRetrySSHSucceedsAfterTransientFailures(t *testing.T) {
+	runner := &fakeSSHRunner{results: []fakeSSHResult{
+		{output: "Failed to connect to docker daemon", err: errors.New("exit 1")},
+		{output: "Cannot acquire lock /var/lib/swupd", err: errors.New("exit 1")},
+		{output: "ok", err: nil},
+	}}
+
+	output, err := RetrySSH(runner, "swupd bundle-add docker", fastRetryOptions())
+	if err != nil {
+		t.Fatalf("expected eventual success, got err=%v", err)
+	}
+	if output != "ok" {
+		t.Fatalf("expected final successful output, got %q", output)
+	}
+	if runner.calls != 3 {
+		t.Fatalf("expected 3 attempts, got %d", runner.calls)
+	}
+}
+
+func TestRetrySSHReturnsImmediatelyOnNonRetryableError(t *testing.T) {
+	runner := &fakeSSHRunner{results: []fakeSSHResult{
+		{output: "bundle not found", err: errors.New("exit 1")},
+	}}
+
+	_, err := RetrySSH(runner, "swupd bundle-add nonexistent", fastRetryOptions())
+	if err == nil {
+		t.Fatal("expected error for non-retryable output")
+	}
+	if runner.calls != 1 {
+		t.Fatalf("expected no retries for a non-retryable error, got %d attempts", runner.calls)
+	}
+}
+
+func TestRetrySSHGivesUpAfterMaxElapsedTime(t *testing.T) {
+	runner := &fakeSSHRunner{results: []fakeSSHResult{
+		{output: "Failed to connect to docker daemon", err: errors.New("exit 1")},
+	}}
+
+	opts := fastRetryOptions()
+	opts.MaxElapsedTime = 5 * time.Millisecond
+
+	_, err := RetrySSH(runner, "swupd bundle-add docker", opts)
+	if err == nil {
+		t.Fatal("expected error once the retry deadline elapses")
+	}
+	if runner.calls < 1 {
+		t.Fatalf("expected at least one attempt, got %d", runner.calls)
+	}
+}
@@ -0,0 +1,43 @@
+package runtime
+
+import "fmt"
+
+// Containerd runs containerd directly, without the dockerd layer on top.
+// Clear Linux's "containers-basic" bundle already pulls it in, so there is
+// no separate bundle to install.
+type Containerd struct{}
+
+func (r *Containerd) Name() string { return "containerd" }
+
+func (r *Containerd) Install(p Provisioner) error {
+	_, err := p.SSHCommand("swupd bundle-add containers-basic")
+	return err
+}
+
+func (r *Containerd) GenerateUnit(p Provisioner, cfg Config) (UnitFile, error) {
+	if err := RejectDockerOnlyOptions(r.Name(), cfg); err != nil {
+		return UnitFile{}, err
+	}
+
+	contents := `[Service]
+Environment=TMPDIR=/var/tmp
+ExecStart=
+ExecStart=/usr/bin/containerd
+`
+	for _, env := range cfg.Env {
+		contents += fmt.Sprintf("Environment=%q\n", env)
+	}
+
+	return UnitFile{
+		Path:     "/etc/systemd/system/containerd.service.d/10-machine.conf",
+		Contents: contents,
+	}, nil
+}
+
+func (r *Containerd) SocketPath() string {
+	return "/run/containerd/containerd.sock"
+}
+
+func (r *Containerd) ClientVersion(p Provisioner) (string, error) {
+	return p.SSHCommand("ctr version | awk '/^Version/{print $2}'")
+}
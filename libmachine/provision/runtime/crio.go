@@ -0,0 +1,42 @@
+package runtime
+
+import "fmt"
+
+// CRIO runs CRI-O, the CRI-native container runtime that is also shipped
+// as its own Clear Linux bundle.
+type CRIO struct{}
+
+func (r *CRIO) Name() string { return "cri-o" }
+
+func (r *CRIO) Install(p Provisioner) error {
+	_, err := p.SSHCommand("swupd bundle-add cri-o")
+	return err
+}
+
+func (r *CRIO) GenerateUnit(p Provisioner, cfg Config) (UnitFile, error) {
+	if err := RejectDockerOnlyOptions(r.Name(), cfg); err != nil {
+		return UnitFile{}, err
+	}
+
+	contents := `[Service]
+Environment=TMPDIR=/var/tmp
+ExecStart=
+ExecStart=/usr/bin/crio
+`
+	for _, env := range cfg.Env {
+		contents += fmt.Sprintf("Environment=%q\n", env)
+	}
+
+	return UnitFile{
+		Path:     "/etc/systemd/system/crio.service.d/10-machine.conf",
+		Contents: contents,
+	}, nil
+}
+
+func (r *CRIO) SocketPath() string {
+	return "/var/run/crio/crio.sock"
+}
+
+func (r *CRIO) ClientVersion(p Provisioner) (string, error) {
+	return p.SSHCommand("crio --version | awk '/Version/{print $2}'")
+}
@@ -0,0 +1,108 @@
+// Package runtime abstracts over the non-default container runtimes a
+// provisioner can install and configure (containerd, cri-o, ...). Plain
+// dockerd stays the implicit default and is handled directly by the
+// provisioners themselves, since it predates this package and already has
+// its own install/unit-generation path; this package only covers the
+// alternatives a provisioner can opt into via engine.Options.Runtime.
+package runtime
+
+import "fmt"
+
+// ErrDockerOnlyOption is returned by a Runtime's GenerateUnit when the
+// caller set a Config field that only makes sense for the dockerd engine
+// (docker's --label/--insecure-registry/--registry-mirror flags have no
+// equivalent on containerd or cri-o). Rather than silently dropping the
+// setting, implementations reject it so the mismatch surfaces immediately.
+type ErrDockerOnlyOption struct {
+	Runtime string
+	Option  string
+}
+
+func (e *ErrDockerOnlyOption) Error() string {
+	return fmt.Sprintf("%s runtime does not support EngineOptions.%s", e.Runtime, e.Option)
+}
+
+// RejectDockerOnlyOptions returns an *ErrDockerOnlyOption for the first
+// docker-only Config field that's set, or nil if none are. Runtime
+// implementations that can't honor Labels/InsecureRegistry/RegistryMirror
+// call this at the top of GenerateUnit.
+func RejectDockerOnlyOptions(runtimeName string, cfg Config) error {
+	switch {
+	case len(cfg.Labels) > 0:
+		return &ErrDockerOnlyOption{Runtime: runtimeName, Option: "Labels"}
+	case len(cfg.InsecureRegistry) > 0:
+		return &ErrDockerOnlyOption{Runtime: runtimeName, Option: "InsecureRegistry"}
+	case len(cfg.RegistryMirror) > 0:
+		return &ErrDockerOnlyOption{Runtime: runtimeName, Option: "RegistryMirror"}
+	default:
+		return nil
+	}
+}
+
+// UnitFile is a systemd unit (or drop-in) that a Runtime wants written to
+// the host, identified by its remote path.
+type UnitFile struct {
+	Path     string
+	Contents string
+}
+
+// Config carries the settings a Runtime needs to render its unit file(s),
+// independent of which specific runtime is in use.
+type Config struct {
+	DockerPort       int
+	SocketGroup      string
+	Labels           []string
+	Env              []string
+	InsecureRegistry []string
+	RegistryMirror   []string
+}
+
+// Provisioner is the subset of provision.Provisioner a Runtime needs in
+// order to install itself and query the host. It's expressed as its own
+// interface here (rather than importing provision.Provisioner directly) to
+// avoid an import cycle between provision and provision/runtime.
+type Provisioner interface {
+	SSHCommand(string) (string, error)
+}
+
+// Runtime is implemented by each supported non-docker container runtime.
+type Runtime interface {
+	// Name is the runtime identifier as accepted in engine.Options.Runtime
+	// (e.g. "containerd", "cri-o").
+	Name() string
+
+	// Install installs the runtime's package(s) on the host.
+	Install(p Provisioner) error
+
+	// GenerateUnit renders the single systemd unit drop-in needed to run
+	// the daemon with the given configuration.
+	GenerateUnit(p Provisioner, cfg Config) (UnitFile, error)
+
+	// SocketPath returns the path of the runtime's control socket.
+	SocketPath() string
+
+	// ClientVersion returns the version of the runtime's CLI client as
+	// reported by the host.
+	ClientVersion(p Provisioner) (string, error)
+}
+
+// runtimes holds the registered Runtime implementations, keyed by Name().
+var runtimes = map[string]Runtime{}
+
+// Register makes a Runtime available to Get.
+func Register(r Runtime) {
+	runtimes[r.Name()] = r
+}
+
+// Get looks up a registered Runtime by name. "docker" and "" are never
+// registered here: plain dockerd is the caller's default path, not a
+// Runtime implementation, so callers must check for those before calling Get.
+func Get(name string) (Runtime, bool) {
+	r, ok := runtimes[name]
+	return r, ok
+}
+
+func init() {
+	Register(&Containerd{})
+	Register(&CRIO{})
+}
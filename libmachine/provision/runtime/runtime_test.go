@@ -0,0 +1,46 @@
+package runtime
+
+import "testing"
+
+func TestGetReturnsRegisteredRuntimes(t *testing.T) {
+	for _, name := range []string{"containerd", "cri-o"} {
+		rt, ok := Get(name)
+		if !ok {
+			t.Fatalf("expected %q to be registered", name)
+		}
+		if rt.Name() != name {
+			t.Fatalf("expected Name() to be %q, got %q", name, rt.Name())
+		}
+	}
+}
+
+func TestGetDoesNotRegisterDocker(t *testing.T) {
+	for _, name := range []string{"docker", ""} {
+		if _, ok := Get(name); ok {
+			t.Fatalf("expected %q to not be registered, dockerd is the caller's default path", name)
+		}
+	}
+}
+
+func TestRejectDockerOnlyOptions(t *testing.T) {
+	cases := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{name: "no docker-only options set", cfg: Config{DockerPort: 2376, Env: []string{"FOO=bar"}}, wantErr: false},
+		{name: "labels set", cfg: Config{Labels: []string{"provider=test"}}, wantErr: true},
+		{name: "insecure registry set", cfg: Config{InsecureRegistry: []string{"10.0.0.1:5000"}}, wantErr: true},
+		{name: "registry mirror set", cfg: Config{RegistryMirror: []string{"https://mirror.example.com"}}, wantErr: true},
+	}
+
+	for _, c := range cases {
+		err := RejectDockerOnlyOptions("containerd", c.cfg)
+		if c.wantErr && err == nil {
+			t.Errorf("%s: expected error, got nil", c.name)
+		}
+		if !c.wantErr && err != nil {
+			t.Errorf("%s: expected no error, got %v", c.name, err)
+		}
+	}
+}
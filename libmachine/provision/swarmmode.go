@@ -0,0 +1,78 @@
+package provision
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/docker/machine/libmachine/log"
+	"github.com/docker/machine/libmachine/swarm"
+)
+
+// configureSwarmMode brings up native swarm-mode (docker 1.12+) on the
+// host, as an alternative to the legacy configureSwarm container-based
+// bootstrap. On a swarm master it initializes the cluster and returns the
+// resulting join tokens; on a worker/secondary manager it joins the
+// cluster the master advertises and returns a zero JoinTokens.
+//
+// configureSwarmMode takes swarmOptions by value and can't mutate the
+// caller's provisioner itself (Provisioner is an interface with no swarm
+// options setter), so the returned tokens must be assigned back onto
+// provisioner.SwarmOptions by the caller — the same way Provision already
+// threads AuthOptions/EngineOptions back onto the provisioner for the
+// libmachine/host layer to persist to the machine store once Provision
+// returns.
+func configureSwarmMode(p Provisioner, swarmOptions swarm.Options) (swarm.JoinTokens, error) {
+	if !swarmOptions.IsSwarm {
+		return swarm.JoinTokens{}, nil
+	}
+
+	if swarmOptions.Master {
+		advertiseAddr, err := p.GetDriver().GetIP()
+		if err != nil {
+			return swarm.JoinTokens{}, err
+		}
+
+		log.Debugf("initializing swarm-mode cluster: advertise-addr=%s", advertiseAddr)
+		cmd := fmt.Sprintf("docker swarm init --advertise-addr %s --listen-addr 0.0.0.0:2377", advertiseAddr)
+		if _, err := p.SSHCommand(cmd); err != nil {
+			return swarm.JoinTokens{}, err
+		}
+
+		managerToken, err := swarmJoinToken(p, "manager")
+		if err != nil {
+			return swarm.JoinTokens{}, err
+		}
+		workerToken, err := swarmJoinToken(p, "worker")
+		if err != nil {
+			return swarm.JoinTokens{}, err
+		}
+
+		return swarm.JoinTokens{Manager: managerToken, Worker: workerToken}, nil
+	}
+
+	token := swarmOptions.JoinTokens.Worker
+	if swarmOptions.ManagerJoin {
+		token = swarmOptions.JoinTokens.Manager
+	}
+
+	addr := fmt.Sprintf("%s:2377", swarmOptions.MasterAddr)
+	return swarm.JoinTokens{}, SwarmJoin(p, token, addr)
+}
+
+func swarmJoinToken(p Provisioner, role string) (string, error) {
+	out, err := p.SSHCommand(fmt.Sprintf("docker swarm join-token -q %s", role))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// SwarmJoin joins the host to an existing swarm-mode cluster using the
+// given join token and manager address (host:2377). It's exposed as a
+// standalone helper so provisioners other than ClearLinux can adopt
+// swarm-mode without duplicating the docker CLI invocation.
+func SwarmJoin(p Provisioner, token, addr string) error {
+	log.Debugf("joining swarm-mode cluster: addr=%s", addr)
+	_, err := p.SSHCommand(fmt.Sprintf("docker swarm join --token %s %s", token, addr))
+	return err
+}
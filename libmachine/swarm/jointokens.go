@@ -0,0 +1,10 @@
+package swarm
+
+// JoinTokens holds the two tokens a swarm-mode cluster hands out for
+// joining as a worker or as an additional manager. They're generated once
+// by the first `docker swarm init` on the master and persisted to the
+// machine store so later machines can join without re-deriving them.
+type JoinTokens struct {
+	Worker  string
+	Manager string
+}
@@ -0,0 +1,39 @@
+package swarm
+
+// Options carries the swarm settings a driver/provisioner is configured
+// with, as read from the CLI flags and persisted on the host in the
+// machine store.
+type Options struct {
+	IsSwarm        bool
+	Image          string
+	Agent          bool
+	Master         bool
+	Discovery      string
+	Address        string
+	Host           string
+	Strategy       string
+	ArbitraryFlags []string
+
+	// Mode selects how swarm is bootstrapped: "classic" (the legacy
+	// `swarm` container, the default when empty), "swarm-mode" (the
+	// engine-native orchestration added in Docker 1.12), or "none".
+	Mode string
+
+	// MasterAddr is the advertised host (IP or name, without a port) of the
+	// swarm-mode manager; port 2377 is appended when joining. Only
+	// meaningful when Mode is "swarm-mode" and Master is false; it's where a
+	// joining worker/manager points `docker swarm join` at.
+	MasterAddr string
+
+	// JoinTokens holds the worker/manager tokens generated by `docker
+	// swarm init` on the swarm-mode master, so later machines can join
+	// the same cluster without re-deriving them.
+	JoinTokens JoinTokens
+
+	// ManagerJoin selects which JoinTokens field a non-master host joins
+	// the swarm-mode cluster with: false (the default) joins as a worker
+	// using JoinTokens.Worker, true joins as an additional manager using
+	// JoinTokens.Manager. Only meaningful when Mode is "swarm-mode" and
+	// Master is false.
+	ManagerJoin bool
+}